@@ -29,8 +29,6 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/klog"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/duration"
 	clientset "k8s.io/client-go/kubernetes"
 	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
@@ -52,6 +50,7 @@ import (
 func NewCmdToken(out io.Writer, errW io.Writer) *cobra.Command {
 	var kubeConfigFile string
 	var dryRun bool
+	var tokenStoreSpec string
 	tokenCmd := &cobra.Command{
 		Use:   "token",
 		Short: "Manage bootstrap tokens",
@@ -85,6 +84,15 @@ func NewCmdToken(out io.Writer, errW io.Writer) *cobra.Command {
 	options.AddKubeConfigFlag(tokenCmd.PersistentFlags(), &kubeConfigFile)
 	tokenCmd.PersistentFlags().BoolVar(&dryRun,
 		options.DryRun, dryRun, "Whether to enable dry-run mode or not")
+	tokenCmd.PersistentFlags().StringVar(&tokenStoreSpec,
+		"token-store", "secret", `Backend used to store bootstrap tokens. One of "secret" (a Secret in the kube-system namespace; the default) or "file:<path>" (a local JSON file, for offline/airgapped token pre-provisioning).`)
+
+	newTokenStore := func() (tokenphase.TokenStore, error) {
+		return tokenphase.NewTokenStore(tokenStoreSpec, func() (clientset.Interface, error) {
+			kubeConfigFile = cmdutil.GetKubeConfigPath(kubeConfigFile)
+			return getClientset(kubeConfigFile, dryRun)
+		}, errW)
+	}
 
 	cfg := &kubeadmapiv1beta2.InitConfiguration{}
 
@@ -94,6 +102,7 @@ func NewCmdToken(out io.Writer, errW io.Writer) *cobra.Command {
 	var cfgPath string
 	var printJoinCommand bool
 	bto := options.NewBootstrapTokenOptions()
+	createPrintFlags := &tokenPrintFlags{}
 
 	createCmd := &cobra.Command{
 		Use:                   "create [token]",
@@ -120,14 +129,13 @@ func NewCmdToken(out io.Writer, errW io.Writer) *cobra.Command {
 				return err
 			}
 
-			klog.V(1).Infoln("[token] getting Clientsets from kubeconfig file")
 			kubeConfigFile = cmdutil.GetKubeConfigPath(kubeConfigFile)
-			client, err := getClientset(kubeConfigFile, dryRun)
+			store, err := newTokenStore()
 			if err != nil {
 				return err
 			}
 
-			return RunCreateToken(out, client, cfgPath, cfg, printJoinCommand, kubeConfigFile)
+			return RunCreateToken(out, store, cfgPath, cfg, printJoinCommand, kubeConfigFile, createPrintFlags)
 		},
 	}
 
@@ -138,10 +146,13 @@ func NewCmdToken(out io.Writer, errW io.Writer) *cobra.Command {
 	bto.AddUsagesFlag(createCmd.Flags())
 	bto.AddGroupsFlag(createCmd.Flags())
 	bto.AddDescriptionFlag(createCmd.Flags())
+	createPrintFlags.AddFlag(createCmd.Flags())
 
 	tokenCmd.AddCommand(createCmd)
 	tokenCmd.AddCommand(NewCmdTokenGenerate(out))
 
+	listPrintFlags := &tokenPrintFlags{}
+	listOpts := &tokenListOptions{}
 	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List bootstrap tokens on the server",
@@ -149,15 +160,16 @@ func NewCmdToken(out io.Writer, errW io.Writer) *cobra.Command {
 			This command will list all bootstrap tokens for you.
 		`),
 		RunE: func(tokenCmd *cobra.Command, args []string) error {
-			kubeConfigFile = cmdutil.GetKubeConfigPath(kubeConfigFile)
-			client, err := getClientset(kubeConfigFile, dryRun)
+			store, err := newTokenStore()
 			if err != nil {
 				return err
 			}
 
-			return RunListTokens(out, errW, client)
+			return RunListTokens(out, store, listPrintFlags, listOpts)
 		},
 	}
+	listPrintFlags.AddFlag(listCmd.Flags())
+	listOpts.AddFlags(listCmd.Flags())
 	tokenCmd.AddCommand(listCmd)
 
 	deleteCmd := &cobra.Command{
@@ -174,17 +186,45 @@ func NewCmdToken(out io.Writer, errW io.Writer) *cobra.Command {
 			if len(args) < 1 {
 				return errors.Errorf("missing subcommand; 'token delete' is missing token of form %q", bootstrapapi.BootstrapTokenIDPattern)
 			}
-			kubeConfigFile = cmdutil.GetKubeConfigPath(kubeConfigFile)
-			client, err := getClientset(kubeConfigFile, dryRun)
+			store, err := newTokenStore()
 			if err != nil {
 				return err
 			}
 
-			return RunDeleteTokens(out, client, args)
+			return RunDeleteTokens(out, store, args)
 		},
 	}
 	tokenCmd.AddCommand(deleteCmd)
 
+	var rotatePrintJoinCommand bool
+	rotateCmd := &cobra.Command{
+		Use:                   "rotate [token-value|token-id]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Rotate a bootstrap token on the server",
+		Long: dedent.Dedent(`
+			This command will create a new bootstrap token with the same usages, extra groups,
+			description and remaining TTL as an existing one, and then delete the existing token.
+
+			The [token-value|token-id] is the full Token of the form "[a-z0-9]{6}.[a-z0-9]{16}" or the
+			Token ID of the form "[a-z0-9]{6}" to rotate.
+		`),
+		RunE: func(tokenCmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.Errorf("expected exactly one token of form %q; got %d", bootstrapapi.BootstrapTokenIDPattern, len(args))
+			}
+			kubeConfigFile = cmdutil.GetKubeConfigPath(kubeConfigFile)
+			store, err := newTokenStore()
+			if err != nil {
+				return err
+			}
+
+			return RunRotateTokens(out, store, args[0], rotatePrintJoinCommand, kubeConfigFile)
+		},
+	}
+	rotateCmd.Flags().BoolVar(&rotatePrintJoinCommand,
+		"print-join-command", false, "Instead of printing only the new token, print the full 'kubeadm join' flag needed to join the cluster using the new token.")
+	tokenCmd.AddCommand(rotateCmd)
+
 	return tokenCmd
 }
 
@@ -210,8 +250,8 @@ func NewCmdTokenGenerate(out io.Writer) *cobra.Command {
 	}
 }
 
-// RunCreateToken generates a new bootstrap token and stores it as a secret on the server.
-func RunCreateToken(out io.Writer, client clientset.Interface, cfgPath string, initCfg *kubeadmapiv1beta2.InitConfiguration, printJoinCommand bool, kubeConfigFile string) error {
+// RunCreateToken generates a new bootstrap token and stores it via the given TokenStore.
+func RunCreateToken(out io.Writer, store tokenphase.TokenStore, cfgPath string, initCfg *kubeadmapiv1beta2.InitConfiguration, printJoinCommand bool, kubeConfigFile string, printFlags *tokenPrintFlags) error {
 	// ClusterConfiguration is needed just for the call to LoadOrDefaultInitConfiguration
 	clusterCfg := &kubeadmapiv1beta2.ClusterConfiguration{
 		// KubernetesVersion is not used, but we set this explicitly to avoid
@@ -234,8 +274,10 @@ func RunCreateToken(out io.Writer, client clientset.Interface, cfgPath string, i
 	}
 
 	klog.V(1).Infoln("[token] creating token")
-	if err := tokenphase.CreateNewTokens(client, internalcfg.BootstrapTokens); err != nil {
-		return err
+	for _, token := range internalcfg.BootstrapTokens {
+		if err := store.Create(token); err != nil {
+			return err
+		}
 	}
 
 	// if --print-join-command was specified, print a machine-readable full `kubeadm join` command
@@ -249,8 +291,80 @@ func RunCreateToken(out io.Writer, client clientset.Interface, cfgPath string, i
 		joinCommand = strings.ReplaceAll(joinCommand, "\\\n", "")
 		joinCommand = strings.ReplaceAll(joinCommand, "\t", "")
 		fmt.Fprintln(out, joinCommand)
+		return nil
+	}
+
+	return printFlags.Print([]*kubeadmapi.BootstrapToken{&internalcfg.BootstrapTokens[0]}, out,
+		func(w io.Writer, tokens []*kubeadmapi.BootstrapToken) error {
+			fmt.Fprintln(w, tokens[0].Token.String())
+			return nil
+		})
+}
+
+// RunRotateTokens creates a new bootstrap token with the same usages, extra groups, description
+// and remaining TTL as an existing one, and then deletes the existing token. This lets an
+// operator roll a token over in a single call instead of running "token create" and
+// "token delete" and hand-copying the attributes in between.
+func RunRotateTokens(out io.Writer, store tokenphase.TokenStore, tokenIDOrToken string, printJoinCommand bool, kubeConfigFile string) error {
+	tokenID := tokenIDOrToken
+	if !bootstraputil.IsValidBootstrapTokenID(tokenID) {
+		bts, err := kubeadmapiv1beta2.NewBootstrapTokenString(tokenIDOrToken)
+		if err != nil {
+			return errors.Errorf("given token %q didn't match pattern %q or %q",
+				tokenIDOrToken, bootstrapapi.BootstrapTokenIDPattern, bootstrapapi.BootstrapTokenPattern)
+		}
+		tokenID = bts.ID
+	}
+
+	klog.V(1).Infof("[token] retrieving bootstrap token %q", tokenID)
+	oldToken, err := store.Get(tokenID)
+	if err != nil {
+		return err
+	}
+	if oldToken.Expires != nil && oldToken.Expires.Time.Before(time.Now()) {
+		return errors.Errorf("bootstrap token %q has already expired; delete it and create a new one instead of rotating it", tokenID)
+	}
+
+	klog.V(1).Infoln("[token] creating replacement token")
+	tokenStr, err := bootstraputil.GenerateBootstrapToken()
+	if err != nil {
+		return errors.Wrap(err, "failed to generate a new bootstrap token")
+	}
+	bts, err := kubeadmapi.NewBootstrapTokenString(tokenStr)
+	if err != nil {
+		return err
+	}
+	newToken := kubeadmapi.BootstrapToken{
+		Token:       bts,
+		Description: oldToken.Description,
+		TTL:         oldToken.TTL,
+		Expires:     oldToken.Expires,
+		Usages:      oldToken.Usages,
+		Groups:      oldToken.Groups,
+	}
+	if err := store.Create(newToken); err != nil {
+		return err
+	}
+
+	// Print (or hand out the join command for) the replacement token before attempting to
+	// delete the old one: if the delete below fails, the operator still learns about the new,
+	// already-live token instead of being left to discover it by running "token list".
+	if printJoinCommand {
+		skipTokenPrint := false
+		joinCommand, err := cmdutil.GetJoinWorkerCommand(kubeConfigFile, newToken.Token.String(), skipTokenPrint)
+		if err != nil {
+			return errors.Wrap(err, "failed to get join command")
+		}
+		joinCommand = strings.ReplaceAll(joinCommand, "\\\n", "")
+		joinCommand = strings.ReplaceAll(joinCommand, "\t", "")
+		fmt.Fprintln(out, joinCommand)
 	} else {
-		fmt.Fprintln(out, internalcfg.BootstrapTokens[0].Token.String())
+		fmt.Fprintln(out, newToken.Token.String())
+	}
+
+	klog.V(1).Infof("[token] deleting replaced token %q", tokenID)
+	if err := store.Delete(tokenID); err != nil {
+		return errors.Wrapf(err, "failed to delete replaced bootstrap token %q", tokenID)
 	}
 
 	return nil
@@ -269,52 +383,50 @@ func RunGenerateToken(out io.Writer) error {
 }
 
 // RunListTokens lists details on all existing bootstrap tokens on the server.
-func RunListTokens(out io.Writer, errW io.Writer, client clientset.Interface) error {
-	// First, build our selector for bootstrap tokens only
-	klog.V(1).Infoln("[token] preparing selector for bootstrap token")
-	tokenSelector := fields.SelectorFromSet(
-		map[string]string{
-			// TODO: We hard-code "type" here until `field_constants.go` that is
-			// currently in `pkg/apis/core/` exists in the external API, i.e.
-			// k8s.io/api/v1. Should be v1.SecretTypeField
-			"type": string(bootstrapapi.SecretTypeBootstrapToken),
-		},
-	)
-	listOptions := metav1.ListOptions{
-		FieldSelector: tokenSelector.String(),
+func RunListTokens(out io.Writer, store tokenphase.TokenStore, printFlags *tokenPrintFlags, listOpts *tokenListOptions) error {
+	klog.V(1).Infoln("[token] retrieving list of bootstrap tokens")
+	tokens, err := store.List()
+	if err != nil {
+		return err
 	}
 
-	klog.V(1).Infoln("[token] retrieving list of bootstrap tokens")
-	secrets, err := client.CoreV1().Secrets(metav1.NamespaceSystem).List(listOptions)
+	tokens, err = listOpts.apply(tokens)
 	if err != nil {
-		return errors.Wrap(err, "failed to list bootstrap tokens")
+		return err
 	}
 
-	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
-	fmt.Fprintln(w, "TOKEN\tTTL\tEXPIRES\tUSAGES\tDESCRIPTION\tEXTRA GROUPS")
-	for _, secret := range secrets.Items {
+	return printFlags.Print(tokens, out, func(w io.Writer, tokens []*kubeadmapi.BootstrapToken) error {
+		return printBootstrapTokensText(w, tokens, listOpts.noHeaders, listOpts.showLabels)
+	})
+}
 
-		// Get the BootstrapToken struct representation from the Secret object
-		token, err := kubeadmapi.BootstrapTokenFromSecret(&secret)
-		if err != nil {
-			fmt.Fprintf(errW, "%v", err)
-			continue
+// printBootstrapTokensText is the "text" (default) output format for "token list"; it keeps the
+// historical tabwriter-aligned column layout.
+func printBootstrapTokensText(out io.Writer, tokens []*kubeadmapi.BootstrapToken, noHeaders, showLabels bool) error {
+	w := tabwriter.NewWriter(out, 10, 4, 3, ' ', 0)
+	if !noHeaders {
+		header := "TOKEN\tTTL\tEXPIRES\tUSAGES\tDESCRIPTION\tEXTRA GROUPS"
+		if showLabels {
+			header += "\tLABELS"
 		}
-
-		// Get the human-friendly string representation for the token
-		humanFriendlyTokenOutput := humanReadableBootstrapToken(token)
-		fmt.Fprintln(w, humanFriendlyTokenOutput)
+		fmt.Fprintln(w, header)
 	}
-	w.Flush()
-	return nil
+	for _, token := range tokens {
+		line := humanReadableBootstrapToken(token)
+		if showLabels {
+			line += "\t" + tokenLabels(token)
+		}
+		fmt.Fprintln(w, line)
+	}
+	return w.Flush()
 }
 
 // RunDeleteTokens removes a bootstrap tokens from the server.
-func RunDeleteTokens(out io.Writer, client clientset.Interface, tokenIDsOrTokens []string) error {
+func RunDeleteTokens(out io.Writer, store tokenphase.TokenStore, tokenIDsOrTokens []string) error {
 	for _, tokenIDOrToken := range tokenIDsOrTokens {
 		// Assume this is a token id and try to parse it
 		tokenID := tokenIDOrToken
-		klog.V(1).Infof("[token] parsing token %q", tokenIDOrToken)
+		klog.V(1).Infof("[token] parsing token %q", sanitizeToken(tokenIDOrToken))
 		if !bootstraputil.IsValidBootstrapTokenID(tokenIDOrToken) {
 			// Okay, the full token with both id and secret was probably passed. Parse it and extract the ID only
 			bts, err := kubeadmapiv1beta2.NewBootstrapTokenString(tokenIDOrToken)
@@ -325,9 +437,8 @@ func RunDeleteTokens(out io.Writer, client clientset.Interface, tokenIDsOrTokens
 			tokenID = bts.ID
 		}
 
-		tokenSecretName := bootstraputil.BootstrapTokenSecretName(tokenID)
 		klog.V(1).Infof("[token] deleting token %q", tokenID)
-		if err := client.CoreV1().Secrets(metav1.NamespaceSystem).Delete(tokenSecretName, nil); err != nil {
+		if err := store.Delete(tokenID); err != nil {
 			return errors.Wrapf(err, "failed to delete bootstrap token %q", tokenID)
 		}
 		fmt.Fprintf(out, "bootstrap token %q deleted\n", tokenID)
@@ -335,6 +446,23 @@ func RunDeleteTokens(out io.Writer, client clientset.Interface, tokenIDsOrTokens
 	return nil
 }
 
+// sanitizedTokenSecretPlaceholder replaces the 16-character Token Secret portion of a bootstrap
+// token wherever one might otherwise reach log output.
+const sanitizedTokenSecretPlaceholder = "****************"
+
+// sanitizeToken returns tokenIDOrToken with its Token Secret half, if any, replaced by
+// sanitizedTokenSecretPlaceholder, so callers can safely log a user-supplied token argument
+// before it has been parsed and validated. A bootstrap token is live credential material: with
+// it alone an attacker can join nodes or bootstrap TLS until it expires, so it must never be
+// written to klog output, even at high verbosity.
+func sanitizeToken(tokenIDOrToken string) string {
+	parts := strings.SplitN(tokenIDOrToken, ".", 2)
+	if len(parts) != 2 {
+		return tokenIDOrToken
+	}
+	return parts[0] + "." + sanitizedTokenSecretPlaceholder
+}
+
 func humanReadableBootstrapToken(token *kubeadmapi.BootstrapToken) string {
 	description := token.Description
 	if len(description) == 0 {
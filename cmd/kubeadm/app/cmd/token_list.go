@@ -0,0 +1,129 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+// tokenListOptions holds the client-side filtering and sorting flags for "token list".
+type tokenListOptions struct {
+	selector   string
+	expired    bool
+	notExpired bool
+	sortBy     string
+	noHeaders  bool
+	showLabels bool
+}
+
+// AddFlags registers the filtering and sorting flags for "token list" on the given FlagSet.
+func (o *tokenListOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&o.selector, "selector", "l", "",
+		"Only show tokens whose usages, extra groups or description contain this substring.")
+	fs.BoolVar(&o.expired, "expired", false, "Only show tokens that have already expired.")
+	fs.BoolVar(&o.notExpired, "not-expired", false, "Only show tokens that have not yet expired.")
+	fs.StringVar(&o.sortBy, "sort-by", "",
+		`Sort tokens by the given field; one of "expires", "token" or "description".`)
+	fs.BoolVar(&o.noHeaders, "no-headers", false, "Don't print the column headers.")
+	fs.BoolVar(&o.showLabels, "show-labels", false,
+		"Show an additional column listing each token's usages and extra groups.")
+}
+
+// apply filters and sorts tokens according to o. It does not mutate the input slice.
+func (o *tokenListOptions) apply(tokens []*kubeadmapi.BootstrapToken) ([]*kubeadmapi.BootstrapToken, error) {
+	if o.expired && o.notExpired {
+		return nil, errors.New("--expired and --not-expired are mutually exclusive")
+	}
+	less, err := tokenLessFunc(o.sortBy)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*kubeadmapi.BootstrapToken, 0, len(tokens))
+	for _, token := range tokens {
+		if o.selector != "" && !tokenMatchesSelector(token, o.selector) {
+			continue
+		}
+		isExpired := token.Expires != nil && token.Expires.Time.Before(time.Now())
+		if o.expired && !isExpired {
+			continue
+		}
+		if o.notExpired && isExpired {
+			continue
+		}
+		filtered = append(filtered, token)
+	}
+
+	if less != nil {
+		sort.SliceStable(filtered, func(i, j int) bool { return less(filtered[i], filtered[j]) })
+	}
+	return filtered, nil
+}
+
+func tokenMatchesSelector(token *kubeadmapi.BootstrapToken, selector string) bool {
+	usages := strings.Join(token.Usages, ",")
+	groups := strings.Join(token.Groups, ",")
+	return strings.Contains(usages, selector) || strings.Contains(groups, selector) || strings.Contains(token.Description, selector)
+}
+
+// tokenLessFunc returns the comparator for --sort-by, or nil if tokens should keep their
+// natural (server-returned) order.
+func tokenLessFunc(sortBy string) (func(a, b *kubeadmapi.BootstrapToken) bool, error) {
+	switch sortBy {
+	case "":
+		return nil, nil
+	case "token":
+		return func(a, b *kubeadmapi.BootstrapToken) bool { return a.Token.String() < b.Token.String() }, nil
+	case "description":
+		return func(a, b *kubeadmapi.BootstrapToken) bool { return a.Description < b.Description }, nil
+	case "expires":
+		return func(a, b *kubeadmapi.BootstrapToken) bool {
+			// Tokens that never expire sort last.
+			if a.Expires == nil {
+				return false
+			}
+			if b.Expires == nil {
+				return true
+			}
+			return a.Expires.Time.Before(b.Expires.Time)
+		}, nil
+	default:
+		return nil, errors.Errorf(`invalid --sort-by %q; must be one of "expires", "token" or "description"`, sortBy)
+	}
+}
+
+// tokenLabels renders a token's usages and extra groups as a single "show-labels" column.
+func tokenLabels(token *kubeadmapi.BootstrapToken) string {
+	parts := make([]string, 0, len(token.Usages)+len(token.Groups))
+	for _, usage := range token.Usages {
+		parts = append(parts, "usage="+usage)
+	}
+	for _, group := range token.Groups {
+		parts = append(parts, "group="+group)
+	}
+	if len(parts) == 0 {
+		return "<none>"
+	}
+	return strings.Join(parts, ",")
+}
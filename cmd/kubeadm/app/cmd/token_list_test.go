@@ -0,0 +1,143 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+func newTestListToken(t *testing.T, tokenStr, description string, usages, groups []string, expiresIn time.Duration) *kubeadmapi.BootstrapToken {
+	t.Helper()
+	bts, err := kubeadmapi.NewBootstrapTokenString(tokenStr)
+	if err != nil {
+		t.Fatalf("failed to build bootstrap token string %q: %v", tokenStr, err)
+	}
+	token := &kubeadmapi.BootstrapToken{
+		Token:       bts,
+		Description: description,
+		Usages:      usages,
+		Groups:      groups,
+	}
+	if expiresIn != 0 {
+		expires := metav1.NewTime(time.Now().Add(expiresIn))
+		token.Expires = &expires
+	}
+	return token
+}
+
+func TestTokenMatchesSelector(t *testing.T) {
+	token := newTestListToken(t, "abcdef.0123456789abcdef", "my desc", []string{"signing"}, []string{"system:bootstrappers"}, 0)
+
+	tests := []struct {
+		name     string
+		selector string
+		want     bool
+	}{
+		{"matches a usage", "signing", true},
+		{"matches a group", "bootstrappers", true},
+		{"matches the description", "my desc", true},
+		{"does not match across fields", "signing,system", false},
+		{"does not match an unrelated substring", "nope", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenMatchesSelector(token, tt.selector); got != tt.want {
+				t.Errorf("tokenMatchesSelector(selector=%q) = %v, want %v", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenListOptionsApply(t *testing.T) {
+	t.Run("--expired and --not-expired are mutually exclusive", func(t *testing.T) {
+		o := &tokenListOptions{expired: true, notExpired: true}
+		if _, err := o.apply(nil); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	t.Run("invalid --sort-by is rejected", func(t *testing.T) {
+		o := &tokenListOptions{sortBy: "bogus"}
+		if _, err := o.apply(nil); err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+
+	expired := newTestListToken(t, "aaaaaa.0123456789abcdef", "expired", nil, nil, -time.Hour)
+	notExpired := newTestListToken(t, "bbbbbb.0123456789abcdef", "not expired", nil, nil, time.Hour)
+	neverExpires := newTestListToken(t, "cccccc.0123456789abcdef", "never expires", nil, nil, 0)
+	tokens := []*kubeadmapi.BootstrapToken{neverExpires, expired, notExpired}
+
+	t.Run("--expired keeps only expired tokens", func(t *testing.T) {
+		o := &tokenListOptions{expired: true}
+		got, err := o.apply(tokens)
+		if err != nil {
+			t.Fatalf("apply returned an unexpected error: %v", err)
+		}
+		if len(got) != 1 || got[0] != expired {
+			t.Errorf("apply(--expired) = %v, want only the expired token", got)
+		}
+	})
+
+	t.Run("--not-expired keeps non-expired and never-expiring tokens", func(t *testing.T) {
+		o := &tokenListOptions{notExpired: true}
+		got, err := o.apply(tokens)
+		if err != nil {
+			t.Fatalf("apply returned an unexpected error: %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("apply(--not-expired) returned %d tokens, want 2", len(got))
+		}
+	})
+
+	t.Run("sort-by token", func(t *testing.T) {
+		o := &tokenListOptions{sortBy: "token"}
+		got, err := o.apply(tokens)
+		if err != nil {
+			t.Fatalf("apply returned an unexpected error: %v", err)
+		}
+		if got[0] != expired || got[1] != notExpired || got[2] != neverExpires {
+			t.Errorf("apply(sort-by=token) did not sort by token string: %v", got)
+		}
+	})
+
+	t.Run("sort-by description", func(t *testing.T) {
+		o := &tokenListOptions{sortBy: "description"}
+		got, err := o.apply(tokens)
+		if err != nil {
+			t.Fatalf("apply returned an unexpected error: %v", err)
+		}
+		if got[0].Description != "expired" || got[1].Description != "never expires" || got[2].Description != "not expired" {
+			t.Errorf("apply(sort-by=description) did not sort lexically: %v", got)
+		}
+	})
+
+	t.Run("sort-by expires sorts never-expiring tokens last", func(t *testing.T) {
+		o := &tokenListOptions{sortBy: "expires"}
+		got, err := o.apply(tokens)
+		if err != nil {
+			t.Fatalf("apply returned an unexpected error: %v", err)
+		}
+		if got[0] != expired || got[1] != notExpired || got[2] != neverExpires {
+			t.Errorf("apply(sort-by=expires) did not sort soonest-first with nil last: %v", got)
+		}
+	})
+}
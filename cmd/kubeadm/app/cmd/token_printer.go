@@ -0,0 +1,139 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/util/jsonpath"
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+// tokenPrintFlags holds the "-o/--output" flag shared by "token create" and "token list".
+type tokenPrintFlags struct {
+	format string
+}
+
+// AddFlag registers the "-o/--output" flag on the given FlagSet.
+func (f *tokenPrintFlags) AddFlag(fs *pflag.FlagSet) {
+	fs.StringVarP(&f.format, "output", "o", "text",
+		`Output format; available options are "text", "json", "yaml" and "jsonpath=...".`)
+}
+
+// textPrinterFunc renders tokens in the format used when "-o" is left at its default; callers
+// supply this to keep their own tabwriter-aligned layout.
+type textPrinterFunc func(out io.Writer, tokens []*kubeadmapi.BootstrapToken) error
+
+// Print writes tokens to out using the requested output format, falling back to textFn for the
+// default "text" format.
+func (f *tokenPrintFlags) Print(tokens []*kubeadmapi.BootstrapToken, out io.Writer, textFn textPrinterFunc) error {
+	if f.format == "" || f.format == "text" {
+		return textFn(out, tokens)
+	}
+
+	printables := make([]*bootstrapTokenPrintable, 0, len(tokens))
+	for _, token := range tokens {
+		printables = append(printables, newBootstrapTokenPrintable(token))
+	}
+
+	// A single token is printed as an object rather than a one-element list, matching the
+	// shape an operator would write to a file with "token create -o json".
+	var data interface{} = printables
+	if len(printables) == 1 {
+		data = printables[0]
+	}
+
+	switch {
+	case f.format == "json":
+		b, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal bootstrap tokens to JSON")
+		}
+		fmt.Fprintln(out, string(b))
+	case f.format == "yaml":
+		b, err := yaml.Marshal(data)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal bootstrap tokens to YAML")
+		}
+		fmt.Fprint(out, string(b))
+	case strings.HasPrefix(f.format, "jsonpath="):
+		if err := printJSONPath(out, strings.TrimPrefix(f.format, "jsonpath="), data); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf(`unknown output format %q; must be one of "text", "json", "yaml" or "jsonpath=..."`, f.format)
+	}
+	return nil
+}
+
+// printJSONPath executes template against data, which is first round-tripped through JSON so
+// that field paths match the names used in the "-o json"/"-o yaml" output.
+func printJSONPath(out io.Writer, template string, data interface{}) error {
+	jp := jsonpath.New("token")
+	if err := jp.Parse(template); err != nil {
+		return errors.Wrap(err, "failed to parse jsonpath template")
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return err
+	}
+
+	if err := jp.Execute(out, generic); err != nil {
+		return errors.Wrap(err, "failed to execute jsonpath template")
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+// bootstrapTokenPrintable is the marshalable representation of a kubeadmapi.BootstrapToken used
+// by the "json", "yaml" and "jsonpath=..." output formats.
+type bootstrapTokenPrintable struct {
+	Token       string   `json:"token"`
+	TTL         string   `json:"ttl,omitempty"`
+	Expires     string   `json:"expires,omitempty"`
+	Usages      []string `json:"usages,omitempty"`
+	Groups      []string `json:"groups,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+func newBootstrapTokenPrintable(token *kubeadmapi.BootstrapToken) *bootstrapTokenPrintable {
+	p := &bootstrapTokenPrintable{
+		Token:       token.Token.String(),
+		Usages:      token.Usages,
+		Groups:      token.Groups,
+		Description: token.Description,
+	}
+	if token.Expires != nil {
+		p.TTL = duration.ShortHumanDuration(token.Expires.Sub(time.Now()))
+		p.Expires = token.Expires.Format(time.RFC3339)
+	}
+	return p
+}
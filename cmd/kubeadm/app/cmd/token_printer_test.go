@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+func newTestPrintableToken(t *testing.T, tokenStr, description string) *kubeadmapi.BootstrapToken {
+	t.Helper()
+	bts, err := kubeadmapi.NewBootstrapTokenString(tokenStr)
+	if err != nil {
+		t.Fatalf("failed to build bootstrap token string %q: %v", tokenStr, err)
+	}
+	return &kubeadmapi.BootstrapToken{
+		Token:       bts,
+		Description: description,
+		Usages:      []string{"signing"},
+	}
+}
+
+func failingTextFn(t *testing.T) textPrinterFunc {
+	return func(io.Writer, []*kubeadmapi.BootstrapToken) error {
+		t.Fatal("textFn should not be called for a non-text output format")
+		return nil
+	}
+}
+
+func TestTokenPrintFlagsPrint(t *testing.T) {
+	token := newTestPrintableToken(t, "abcdef.0123456789abcdef", "my token")
+
+	t.Run("json single token prints an object, not a list", func(t *testing.T) {
+		var out bytes.Buffer
+		flags := &tokenPrintFlags{format: "json"}
+		if err := flags.Print([]*kubeadmapi.BootstrapToken{token}, &out, failingTextFn(t)); err != nil {
+			t.Fatalf("Print returned an unexpected error: %v", err)
+		}
+		if strings.HasPrefix(strings.TrimSpace(out.String()), "[") {
+			t.Errorf("expected a single token to be printed as a JSON object, got a list:\n%s", out.String())
+		}
+		if !strings.Contains(out.String(), `"token": "abcdef.0123456789abcdef"`) {
+			t.Errorf("expected output to contain the token field, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("json multiple tokens prints a list", func(t *testing.T) {
+		var out bytes.Buffer
+		flags := &tokenPrintFlags{format: "json"}
+		if err := flags.Print([]*kubeadmapi.BootstrapToken{token, token}, &out, failingTextFn(t)); err != nil {
+			t.Fatalf("Print returned an unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(strings.TrimSpace(out.String()), "[") {
+			t.Errorf("expected multiple tokens to be printed as a JSON list, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var out bytes.Buffer
+		flags := &tokenPrintFlags{format: "yaml"}
+		if err := flags.Print([]*kubeadmapi.BootstrapToken{token}, &out, failingTextFn(t)); err != nil {
+			t.Fatalf("Print returned an unexpected error: %v", err)
+		}
+		if !strings.Contains(out.String(), "token: abcdef.0123456789abcdef") {
+			t.Errorf("expected YAML output to contain the token field, got:\n%s", out.String())
+		}
+	})
+
+	t.Run("jsonpath", func(t *testing.T) {
+		var out bytes.Buffer
+		flags := &tokenPrintFlags{format: "jsonpath={.token}"}
+		if err := flags.Print([]*kubeadmapi.BootstrapToken{token}, &out, failingTextFn(t)); err != nil {
+			t.Fatalf("Print returned an unexpected error: %v", err)
+		}
+		if strings.TrimSpace(out.String()) != "abcdef.0123456789abcdef" {
+			t.Errorf("jsonpath output = %q, want %q", out.String(), "abcdef.0123456789abcdef")
+		}
+	})
+
+	t.Run("text falls back to textFn", func(t *testing.T) {
+		var out bytes.Buffer
+		var gotTokens []*kubeadmapi.BootstrapToken
+		flags := &tokenPrintFlags{format: "text"}
+		err := flags.Print([]*kubeadmapi.BootstrapToken{token}, &out, func(w io.Writer, tokens []*kubeadmapi.BootstrapToken) error {
+			gotTokens = tokens
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Print returned an unexpected error: %v", err)
+		}
+		if len(gotTokens) != 1 {
+			t.Fatalf("expected textFn to receive 1 token, got %d", len(gotTokens))
+		}
+	})
+
+	t.Run("unknown format is rejected", func(t *testing.T) {
+		var out bytes.Buffer
+		flags := &tokenPrintFlags{format: "xml"}
+		if err := flags.Print([]*kubeadmapi.BootstrapToken{token}, &out, failingTextFn(t)); err == nil {
+			t.Error("expected an error for an unknown output format, got nil")
+		}
+	})
+}
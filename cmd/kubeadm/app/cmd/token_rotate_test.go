@@ -0,0 +1,147 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+// fakeTokenStore is an in-memory tokenphase.TokenStore for exercising RunRotateTokens without a
+// fake Kubernetes clientset or a file on disk.
+type fakeTokenStore struct {
+	tokens    map[string]*kubeadmapi.BootstrapToken
+	deleteErr error
+}
+
+func (s *fakeTokenStore) Create(token kubeadmapi.BootstrapToken) error {
+	if s.tokens == nil {
+		s.tokens = map[string]*kubeadmapi.BootstrapToken{}
+	}
+	s.tokens[token.Token.ID] = &token
+	return nil
+}
+
+func (s *fakeTokenStore) Get(tokenID string) (*kubeadmapi.BootstrapToken, error) {
+	token, ok := s.tokens[tokenID]
+	if !ok {
+		return nil, errors.Errorf("no bootstrap token with id %q", tokenID)
+	}
+	return token, nil
+}
+
+func (s *fakeTokenStore) List() ([]*kubeadmapi.BootstrapToken, error) {
+	tokens := make([]*kubeadmapi.BootstrapToken, 0, len(s.tokens))
+	for _, token := range s.tokens {
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func (s *fakeTokenStore) Delete(tokenID string) error {
+	if s.deleteErr != nil {
+		return s.deleteErr
+	}
+	delete(s.tokens, tokenID)
+	return nil
+}
+
+func TestRunRotateTokensInvalidToken(t *testing.T) {
+	store := &fakeTokenStore{}
+	var out bytes.Buffer
+	err := RunRotateTokens(&out, store, "not-a-valid-token", false, "")
+	if err == nil {
+		t.Fatal("expected an error for an invalid token, got nil")
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected nothing to be printed, got %q", out.String())
+	}
+}
+
+func TestRunRotateTokensTokenNotFound(t *testing.T) {
+	store := &fakeTokenStore{}
+	var out bytes.Buffer
+	err := RunRotateTokens(&out, store, "abcdef", false, "")
+	if err == nil {
+		t.Fatal("expected an error for a nonexistent token, got nil")
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected nothing to be printed, got %q", out.String())
+	}
+}
+
+func TestRunRotateTokensRejectsExpiredToken(t *testing.T) {
+	tokenID := "abcdef"
+	expired := metav1.NewTime(time.Now().Add(-time.Hour))
+	store := &fakeTokenStore{tokens: map[string]*kubeadmapi.BootstrapToken{
+		tokenID: newTestStoreTokenForRotate(t, tokenID+".0123456789abcdef", &expired),
+	}}
+
+	var out bytes.Buffer
+	err := RunRotateTokens(&out, store, tokenID, false, "")
+	if err == nil {
+		t.Fatal("expected an error for rotating an already-expired token, got nil")
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected nothing to be printed, got %q", out.String())
+	}
+	if len(store.tokens) != 1 {
+		t.Errorf("expected no replacement token to be created, store has %d tokens", len(store.tokens))
+	}
+}
+
+// TestRunRotateTokensPrintsNewTokenBeforeDeleteError asserts that the replacement token is
+// printed to out even when the subsequent delete of the old token fails, so a partial failure
+// never strands an unannounced live credential.
+func TestRunRotateTokensPrintsNewTokenBeforeDeleteError(t *testing.T) {
+	tokenID := "abcdef"
+	store := &fakeTokenStore{
+		tokens: map[string]*kubeadmapi.BootstrapToken{
+			tokenID: newTestStoreTokenForRotate(t, tokenID+".0123456789abcdef", nil),
+		},
+		deleteErr: errors.New("delete failed"),
+	}
+
+	var out bytes.Buffer
+	err := RunRotateTokens(&out, store, tokenID, false, "")
+	if err == nil {
+		t.Fatal("expected the delete error to propagate, got nil")
+	}
+
+	if !fullBootstrapTokenSecretPattern.MatchString(out.String()) {
+		t.Errorf("expected the replacement token to be printed despite the delete error, got %q", out.String())
+	}
+	if strings.Contains(out.String(), tokenID) {
+		t.Errorf("expected the printed token to be the new one, not the old id %q: %q", tokenID, out.String())
+	}
+}
+
+func newTestStoreTokenForRotate(t *testing.T, tokenStr string, expires *metav1.Time) *kubeadmapi.BootstrapToken {
+	t.Helper()
+	bts, err := kubeadmapi.NewBootstrapTokenString(tokenStr)
+	if err != nil {
+		t.Fatalf("failed to build bootstrap token string %q: %v", tokenStr, err)
+	}
+	return &kubeadmapi.BootstrapToken{Token: bts, Expires: expires}
+}
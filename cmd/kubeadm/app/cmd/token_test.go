@@ -0,0 +1,148 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"regexp"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
+	bootstraputil "k8s.io/cluster-bootstrap/token/util"
+	"k8s.io/klog"
+	kubeadmapiv1beta2 "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm/v1beta2"
+	tokenphase "k8s.io/kubernetes/cmd/kubeadm/app/phases/bootstraptoken/node"
+)
+
+func TestSanitizeToken(t *testing.T) {
+	var tests = []struct {
+		name           string
+		tokenIDOrToken string
+		expected       string
+	}{
+		{
+			name:           "full token is masked",
+			tokenIDOrToken: "abcdef.0123456789abcdef",
+			expected:       "abcdef." + sanitizedTokenSecretPlaceholder,
+		},
+		{
+			name:           "bare token id is left alone",
+			tokenIDOrToken: "abcdef",
+			expected:       "abcdef",
+		},
+	}
+	for _, rt := range tests {
+		t.Run(rt.name, func(t *testing.T) {
+			if actual := sanitizeToken(rt.tokenIDOrToken); actual != rt.expected {
+				t.Errorf("sanitizeToken(%q) = %q, want %q", rt.tokenIDOrToken, actual, rt.expected)
+			}
+		})
+	}
+}
+
+// fullBootstrapTokenSecretPattern matches a full "id.secret" bootstrap token anywhere in a
+// string, i.e. exactly the credential material that must never reach log output.
+var fullBootstrapTokenSecretPattern = regexp.MustCompile(`[a-z0-9]{6}\.[a-z0-9]{16}`)
+
+func newTestBootstrapTokenSecret(t *testing.T, tokenID, tokenSecret string) *v1.Secret {
+	t.Helper()
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bootstraputil.BootstrapTokenSecretName(tokenID),
+			Namespace: metav1.NamespaceSystem,
+		},
+		Type: v1.SecretType(bootstrapapi.SecretTypeBootstrapToken),
+		Data: map[string][]byte{
+			bootstrapapi.BootstrapTokenIDKey:           []byte(tokenID),
+			bootstrapapi.BootstrapTokenSecretKey:       []byte(tokenSecret),
+			bootstrapapi.BootstrapTokenUsageSigningKey: []byte("true"),
+		},
+	}
+}
+
+// TestRunDeleteTokensDoesNotLogSecret asserts that no full bootstrap token ever appears in
+// klog output, including at the V(1) level, when deleting a token by its full value.
+func TestRunDeleteTokensDoesNotLogSecret(t *testing.T) {
+	tokenID := "abcdef"
+	tokenSecret := "0123456789abcdef"
+	client := fake.NewSimpleClientset(newTestBootstrapTokenSecret(t, tokenID, tokenSecret))
+	store, err := tokenphase.NewTokenStore("secret", func() (clientset.Interface, error) { return client, nil }, ioutil.Discard)
+	if err != nil {
+		t.Fatalf("failed to construct token store: %v", err)
+	}
+
+	klogFlags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(klogFlags)
+	klogFlags.Set("logtostderr", "false")
+	klogFlags.Set("v", "5")
+	defer klogFlags.Set("logtostderr", "true")
+	defer klogFlags.Set("v", "0")
+
+	var klogBuf bytes.Buffer
+	klog.SetOutput(&klogBuf)
+	defer klog.SetOutput(ioutil.Discard)
+
+	var out bytes.Buffer
+	if err := RunDeleteTokens(&out, store, []string{tokenID + "." + tokenSecret}); err != nil {
+		t.Fatalf("RunDeleteTokens returned an unexpected error: %v", err)
+	}
+	klog.Flush()
+
+	if fullBootstrapTokenSecretPattern.MatchString(klogBuf.String()) {
+		t.Errorf("klog output leaked a full bootstrap token: %q", klogBuf.String())
+	}
+}
+
+// TestRunCreateTokenDoesNotLogSecret asserts that no full bootstrap token ever appears in klog
+// output, including at the V(1) level, when creating a token. This closes the same gap as
+// TestRunDeleteTokensDoesNotLogSecret for the create path.
+func TestRunCreateTokenDoesNotLogSecret(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	store, err := tokenphase.NewTokenStore("secret", func() (clientset.Interface, error) { return client, nil }, ioutil.Discard)
+	if err != nil {
+		t.Fatalf("failed to construct token store: %v", err)
+	}
+
+	klogFlags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(klogFlags)
+	klogFlags.Set("logtostderr", "false")
+	klogFlags.Set("v", "5")
+	defer klogFlags.Set("logtostderr", "true")
+	defer klogFlags.Set("v", "0")
+
+	var klogBuf bytes.Buffer
+	klog.SetOutput(&klogBuf)
+	defer klog.SetOutput(ioutil.Discard)
+
+	var out bytes.Buffer
+	printJoinCommand := false
+	err = RunCreateToken(&out, store, "", &kubeadmapiv1beta2.InitConfiguration{}, printJoinCommand, "", &tokenPrintFlags{format: "text"})
+	if err != nil {
+		t.Fatalf("RunCreateToken returned an unexpected error: %v", err)
+	}
+	klog.Flush()
+
+	if fullBootstrapTokenSecretPattern.MatchString(klogBuf.String()) {
+		t.Errorf("klog output leaked a full bootstrap token: %q", klogBuf.String())
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package node implements the "bootstrap token" phase of kubeadm: creating, listing and
+// deleting the Secrets (or, via TokenStore, other backends) that back bootstrap tokens.
+package node
+
+import (
+	"github.com/pkg/errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	bootstraputil "k8s.io/cluster-bootstrap/token/util"
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+// CreateNewTokens tries to create a token and fails if one already exists.
+func CreateNewTokens(client clientset.Interface, tokens []kubeadmapi.BootstrapToken) error {
+	return UpdateOrCreateTokens(client, true, tokens)
+}
+
+// UpdateOrCreateTokens attempts to update a token with the given ID, or creates if it does
+// not already exist.
+func UpdateOrCreateTokens(client clientset.Interface, failIfExists bool, tokens []kubeadmapi.BootstrapToken) error {
+	for _, token := range tokens {
+		secretName := bootstraputil.BootstrapTokenSecretName(token.Token.ID)
+		secret, err := client.CoreV1().Secrets(metav1.NamespaceSystem).Get(secretName, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to get bootstrap token %q", token.Token.ID)
+		}
+
+		if secret != nil && err == nil && failIfExists {
+			return errors.Errorf("a bootstrap token with id %q already exists", token.Token.ID)
+		}
+
+		updatedOrNewSecret := token.ToSecret()
+		if apierrors.IsNotFound(err) {
+			if _, err := client.CoreV1().Secrets(metav1.NamespaceSystem).Create(updatedOrNewSecret); err != nil {
+				return errors.Wrap(err, "failed to create bootstrap token secret")
+			}
+			continue
+		}
+
+		updatedOrNewSecret.ResourceVersion = secret.ResourceVersion
+		if _, err := client.CoreV1().Secrets(metav1.NamespaceSystem).Update(updatedOrNewSecret); err != nil {
+			return errors.Wrap(err, "failed to update bootstrap token secret")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	clientset "k8s.io/client-go/kubernetes"
+	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
+	bootstraputil "k8s.io/cluster-bootstrap/token/util"
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+// TokenStore abstracts where bootstrap tokens live, so that "kubeadm token" can work against
+// something other than a kube-system Secret, for example to pre-provision tokens for a node
+// that does not yet have a reachable control plane.
+type TokenStore interface {
+	Create(token kubeadmapi.BootstrapToken) error
+	Get(tokenID string) (*kubeadmapi.BootstrapToken, error)
+	List() ([]*kubeadmapi.BootstrapToken, error)
+	Delete(tokenID string) error
+}
+
+// NewTokenStore builds the TokenStore selected by spec, which is either "secret" (or empty, the
+// default) for the existing kube-system Secret-backed store, or "file:<path>" for a local
+// JSON-file-backed store. getClient is only invoked for the "secret" backend, so selecting a
+// file-backed store never requires a reachable API server. warn, if non-nil, receives
+// non-fatal diagnostics from the "secret" backend (for example a malformed token Secret
+// encountered while listing); if nil, those diagnostics fall back to klog.
+func NewTokenStore(spec string, getClient func() (clientset.Interface, error), warn io.Writer) (TokenStore, error) {
+	switch {
+	case spec == "" || spec == "secret":
+		client, err := getClient()
+		if err != nil {
+			return nil, err
+		}
+		return &secretTokenStore{client: client, warn: warn}, nil
+	case strings.HasPrefix(spec, "file:"):
+		path := strings.TrimPrefix(spec, "file:")
+		if len(path) == 0 {
+			return nil, errors.New(`invalid --token-store "file:"; a path is required`)
+		}
+		return &fileTokenStore{path: path}, nil
+	default:
+		return nil, errors.Errorf(`invalid --token-store %q; must be "secret" or "file:<path>"`, spec)
+	}
+}
+
+// secretTokenStore is the default TokenStore, backed by bootstrap-token Secrets in the
+// kube-system namespace.
+type secretTokenStore struct {
+	client clientset.Interface
+	// warn, if non-nil, receives non-fatal diagnostics instead of klog; see NewTokenStore.
+	warn io.Writer
+}
+
+func (s *secretTokenStore) Create(token kubeadmapi.BootstrapToken) error {
+	return CreateNewTokens(s.client, []kubeadmapi.BootstrapToken{token})
+}
+
+func (s *secretTokenStore) Get(tokenID string) (*kubeadmapi.BootstrapToken, error) {
+	secretName := bootstraputil.BootstrapTokenSecretName(tokenID)
+	secret, err := s.client.CoreV1().Secrets(metav1.NamespaceSystem).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get bootstrap token %q", tokenID)
+	}
+	return kubeadmapi.BootstrapTokenFromSecret(secret)
+}
+
+func (s *secretTokenStore) List() ([]*kubeadmapi.BootstrapToken, error) {
+	tokenSelector := fields.SelectorFromSet(
+		map[string]string{
+			"type": string(bootstrapapi.SecretTypeBootstrapToken),
+		},
+	)
+	secrets, err := s.client.CoreV1().Secrets(metav1.NamespaceSystem).List(metav1.ListOptions{
+		FieldSelector: tokenSelector.String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list bootstrap tokens")
+	}
+
+	tokens := make([]*kubeadmapi.BootstrapToken, 0, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		token, err := kubeadmapi.BootstrapTokenFromSecret(&secret)
+		if err != nil {
+			if s.warn != nil {
+				fmt.Fprintf(s.warn, "[token] ignoring malformed bootstrap token secret %q: %v\n", secret.Name, err)
+			} else {
+				klog.Warningf("[token] ignoring malformed bootstrap token secret %q: %v", secret.Name, err)
+			}
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func (s *secretTokenStore) Delete(tokenID string) error {
+	secretName := bootstraputil.BootstrapTokenSecretName(tokenID)
+	if err := s.client.CoreV1().Secrets(metav1.NamespaceSystem).Delete(secretName, nil); err != nil {
+		return errors.Wrapf(err, "failed to delete bootstrap token %q", tokenID)
+	}
+	return nil
+}
+
+// fileTokenStore is a TokenStore backed by a single JSON file on disk, written with 0600
+// permissions. It is meant for offline/airgapped pre-provisioning, where tokens must be minted
+// before any control plane is reachable; it has no notion of concurrent writers.
+type fileTokenStore struct {
+	path string
+}
+
+func (s *fileTokenStore) load() ([]kubeadmapi.BootstrapToken, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read token store %q", s.path)
+	}
+
+	var tokens []kubeadmapi.BootstrapToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse token store %q", s.path)
+	}
+	return tokens, nil
+}
+
+func (s *fileTokenStore) save(tokens []kubeadmapi.BootstrapToken) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal token store")
+	}
+	if err := ioutil.WriteFile(s.path, data, 0600); err != nil {
+		return errors.Wrapf(err, "failed to write token store %q", s.path)
+	}
+	return nil
+}
+
+func (s *fileTokenStore) Create(token kubeadmapi.BootstrapToken) error {
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	for _, t := range tokens {
+		if t.Token.ID == token.Token.ID {
+			return errors.Errorf("a bootstrap token with id %q already exists in %q", t.Token.ID, s.path)
+		}
+	}
+	return s.save(append(tokens, token))
+}
+
+func (s *fileTokenStore) Get(tokenID string) (*kubeadmapi.BootstrapToken, error) {
+	tokens, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range tokens {
+		if tokens[i].Token.ID == tokenID {
+			return &tokens[i], nil
+		}
+	}
+	return nil, errors.Errorf("no bootstrap token with id %q in %q", tokenID, s.path)
+}
+
+func (s *fileTokenStore) List() ([]*kubeadmapi.BootstrapToken, error) {
+	tokens, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*kubeadmapi.BootstrapToken, 0, len(tokens))
+	for i := range tokens {
+		result = append(result, &tokens[i])
+	}
+	return result, nil
+}
+
+func (s *fileTokenStore) Delete(tokenID string) error {
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	kept := tokens[:0]
+	found := false
+	for _, t := range tokens {
+		if t.Token.ID == tokenID {
+			found = true
+			continue
+		}
+		kept = append(kept, t)
+	}
+	if !found {
+		return errors.Errorf("no bootstrap token with id %q in %q", tokenID, s.path)
+	}
+	return s.save(kept)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	bootstrapapi "k8s.io/cluster-bootstrap/token/api"
+	bootstraputil "k8s.io/cluster-bootstrap/token/util"
+	kubeadmapi "k8s.io/kubernetes/cmd/kubeadm/app/apis/kubeadm"
+)
+
+func newTestStoreToken(t *testing.T, tokenStr string) kubeadmapi.BootstrapToken {
+	t.Helper()
+	bts, err := kubeadmapi.NewBootstrapTokenString(tokenStr)
+	if err != nil {
+		t.Fatalf("failed to build bootstrap token string %q: %v", tokenStr, err)
+	}
+	return kubeadmapi.BootstrapToken{Token: bts, Description: "test token"}
+}
+
+// newTestStoreDir creates a fresh temporary directory and returns it along with a func that
+// removes it; callers are expected to "defer cleanup()".
+func newTestStoreDir(t *testing.T) (dir string, cleanup func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "token-store-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestFileTokenStoreCreateGetListDelete(t *testing.T) {
+	dir, cleanup := newTestStoreDir(t)
+	defer cleanup()
+	store := &fileTokenStore{path: filepath.Join(dir, "tokens.json")}
+
+	tokenA := newTestStoreToken(t, "abcdef.0123456789abcdef")
+	tokenB := newTestStoreToken(t, "ghijkl.0123456789abcdef")
+
+	if err := store.Create(tokenA); err != nil {
+		t.Fatalf("Create(tokenA) returned an unexpected error: %v", err)
+	}
+	if err := store.Create(tokenB); err != nil {
+		t.Fatalf("Create(tokenB) returned an unexpected error: %v", err)
+	}
+
+	got, err := store.Get("abcdef")
+	if err != nil {
+		t.Fatalf("Get(abcdef) returned an unexpected error: %v", err)
+	}
+	if got.Token.String() != tokenA.Token.String() {
+		t.Errorf("Get(abcdef) = %q, want %q", got.Token.String(), tokenA.Token.String())
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("List() returned an unexpected error: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("List() returned %d tokens, want 2", len(list))
+	}
+
+	if err := store.Delete("abcdef"); err != nil {
+		t.Fatalf("Delete(abcdef) returned an unexpected error: %v", err)
+	}
+	list, err = store.List()
+	if err != nil {
+		t.Fatalf("List() after delete returned an unexpected error: %v", err)
+	}
+	if len(list) != 1 || list[0].Token.ID != "ghijkl" {
+		t.Fatalf("List() after delete = %v, want only ghijkl", list)
+	}
+
+	if _, err := store.Get("abcdef"); err == nil {
+		t.Error("Get(abcdef) after delete: expected an error, got nil")
+	}
+}
+
+func TestFileTokenStoreCreateRejectsDuplicateID(t *testing.T) {
+	dir, cleanup := newTestStoreDir(t)
+	defer cleanup()
+	store := &fileTokenStore{path: filepath.Join(dir, "tokens.json")}
+
+	token := newTestStoreToken(t, "abcdef.0123456789abcdef")
+	if err := store.Create(token); err != nil {
+		t.Fatalf("first Create returned an unexpected error: %v", err)
+	}
+
+	duplicate := newTestStoreToken(t, "abcdef.fedcba9876543210")
+	if err := store.Create(duplicate); err == nil {
+		t.Error("Create with a duplicate token id: expected an error, got nil")
+	}
+}
+
+func TestFileTokenStoreMissingFile(t *testing.T) {
+	dir, cleanup := newTestStoreDir(t)
+	defer cleanup()
+	store := &fileTokenStore{path: filepath.Join(dir, "does-not-exist.json")}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("List() on a nonexistent file returned an unexpected error: %v", err)
+	}
+	if len(list) != 0 {
+		t.Fatalf("List() on a nonexistent file = %v, want empty", list)
+	}
+
+	if _, err := store.Get("abcdef"); err == nil {
+		t.Error("Get() on a nonexistent file: expected an error, got nil")
+	}
+
+	if err := store.Delete("abcdef"); err == nil {
+		t.Error("Delete() on a nonexistent file: expected an error, got nil")
+	}
+}
+
+// TestSecretTokenStoreListWarnsAboutMalformedSecretsOnWarnWriter asserts that, when constructed
+// with a non-nil warn writer, secretTokenStore.List reports a malformed bootstrap token secret
+// there instead of only via klog, while still returning the well-formed tokens.
+func TestSecretTokenStoreListWarnsAboutMalformedSecretsOnWarnWriter(t *testing.T) {
+	goodID := "abcdef"
+	malformedSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bootstraputil.BootstrapTokenSecretName("ghijkl"),
+			Namespace: metav1.NamespaceSystem,
+		},
+		Type: v1.SecretType(bootstrapapi.SecretTypeBootstrapToken),
+		// Missing the token id/secret data keys makes this secret unparsable.
+	}
+	goodSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bootstraputil.BootstrapTokenSecretName(goodID),
+			Namespace: metav1.NamespaceSystem,
+		},
+		Type: v1.SecretType(bootstrapapi.SecretTypeBootstrapToken),
+		Data: map[string][]byte{
+			bootstrapapi.BootstrapTokenIDKey:           []byte(goodID),
+			bootstrapapi.BootstrapTokenSecretKey:       []byte("0123456789abcdef"),
+			bootstrapapi.BootstrapTokenUsageSigningKey: []byte("true"),
+		},
+	}
+	client := fake.NewSimpleClientset(malformedSecret, goodSecret)
+
+	var warn bytes.Buffer
+	store := &secretTokenStore{client: client, warn: &warn}
+
+	tokens, err := store.List()
+	if err != nil {
+		t.Fatalf("List() returned an unexpected error: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].Token.ID != goodID {
+		t.Fatalf("List() = %v, want only the well-formed token %q", tokens, goodID)
+	}
+	if !strings.Contains(warn.String(), "ghijkl") {
+		t.Errorf("expected the warn writer to mention the malformed secret, got: %q", warn.String())
+	}
+}
+
+func TestFileTokenStoreSavesWithRestrictivePermissions(t *testing.T) {
+	dir, cleanup := newTestStoreDir(t)
+	defer cleanup()
+	path := filepath.Join(dir, "tokens.json")
+	store := &fileTokenStore{path: path}
+
+	if err := store.Create(newTestStoreToken(t, "abcdef.0123456789abcdef")); err != nil {
+		t.Fatalf("Create returned an unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat token store file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("token store file has permissions %o, want 0600", perm)
+	}
+}